@@ -5,53 +5,84 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/khighness/go-consistent-hashing/registry"
+	"github.com/khighness/go-consistent-hashing/transport"
 )
 
 // @Author KHighness
-// @Update 2022-06-24
+// @Update 2022-09-01
 
 var (
-	port = flag.String("p", "10000", "port")
+	port          = flag.String("p", "10000", "port")
+	grpcPort      = flag.String("grpc-p", "11000", "grpc port")
+	etcdEndpoints = flag.String("etcd", "127.0.0.1:2379", "comma separated etcd endpoints")
 )
 
 func main() {
 	flag.Parse()
 	address := fmt.Sprintf("127.0.0.1:%s", *port)
+	grpcAddress := fmt.Sprintf("127.0.0.1:%s", *grpcPort)
+
+	reg, err := registry.NewEtcdRegistry(strings.Split(*etcdEndpoints, ","))
+	if err != nil {
+		panic(err)
+	}
+
 	server := KVStoreServer{
-		Address:      address,
-		Cache:        sync.Map{},
-		RegistryHost: "http://127.0.0.1:3333",
-		ExpireTime:   10,
+		Address:     address,
+		GRPCAddress: grpcAddress,
+		Registry:    reg,
+		Cache:       sync.Map{},
+		ExpireTime:  10,
 	}
 	server.Start(context.Background())
 }
 
 type KVStoreServer struct {
-	Address      string
-	RegistryHost string
-	Cache        sync.Map
-	ExpireTime   int64
+	Address     string
+	GRPCAddress string
+	Registry    *registry.EtcdRegistry
+	Cache       sync.Map
+	ExpireTime  int64
+
+	grpcServer *grpc.Server
 }
 
 func (s *KVStoreServer) Start(ctx context.Context) {
 	log.Printf("Start kv-server at %s", s.Address)
 
-	var err error
-	if err = s.register(); err != nil {
+	if err := s.Registry.Register(ctx, s.Address); err != nil {
+		panic(err)
+	}
+
+	lis, err := net.Listen("tcp", s.GRPCAddress)
+	if err != nil {
 		panic(err)
 	}
+	s.grpcServer = grpc.NewServer()
+	transport.RegisterKVServiceServer(s.grpcServer, s)
+	go func() {
+		log.Printf("Start kv-server grpc listener at %s", s.GRPCAddress)
+		if err := s.grpcServer.Serve(lis); err != nil {
+			log.Printf("grpc server stopped: %v", err)
+		}
+	}()
 
 	http.HandleFunc("/", s.kvHandler)
-	if err = http.ListenAndServe(s.Address, nil); err != nil {
-		err = s.unregister()
-		if err != nil {
-			panic(err)
+	if err := http.ListenAndServe(s.Address, nil); err != nil {
+		if uerr := s.Registry.Unregister(ctx, s.Address); uerr != nil {
+			panic(uerr)
 		}
 		panic(err)
 	}
@@ -60,49 +91,39 @@ func (s *KVStoreServer) Start(ctx context.Context) {
 	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
 	select {
 	case <-ctx.Done():
-		_ = s.unregister()
+		_ = s.Registry.Unregister(ctx, s.Address)
+		s.grpcServer.GracefulStop()
 		log.Printf("Context done, server stopped")
 	case <-interrupt:
-		_ = s.unregister()
+		_ = s.Registry.Unregister(ctx, s.Address)
+		s.grpcServer.GracefulStop()
 		log.Printf("Stop signal interrupted server")
 	}
 }
 
-func (s *KVStoreServer) kvHandler(w http.ResponseWriter, r *http.Request) {
-	_ = r.ParseForm()
-	key := r.Form["key"][0]
-
+// getOrSet 取出key对应的缓存值，缺失时写入并注册过期删除
+func (s *KVStoreServer) getOrSet(key string) string {
 	if _, ok := s.Cache.Load(key); !ok {
 		val := fmt.Sprintf("k-%s", key)
 		s.Cache.Store(key, val)
 		log.Printf("Cached <%s, %s>", key, val)
-		time.AfterFunc(time.Duration(s.ExpireTime) * time.Second, func() {
+		time.AfterFunc(time.Duration(s.ExpireTime)*time.Second, func() {
 			s.Cache.Delete(key)
 			log.Printf("Removed <%s, %s>", key, val)
 		})
 	}
 
 	val, _ := s.Cache.Load(key)
-	_, _ = fmt.Fprintf(w, val.(string))
+	return val.(string)
 }
 
-func (s *KVStoreServer) register() error {
-	resp, err := http.Get(fmt.Sprintf("%s/register?host=%s", s.RegistryHost, s.Address))
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-	log.Printf("Register to %s", s.RegistryHost)
-	return nil
+func (s *KVStoreServer) kvHandler(w http.ResponseWriter, r *http.Request) {
+	_ = r.ParseForm()
+	key := r.Form["key"][0]
+	_, _ = fmt.Fprintf(w, s.getOrSet(key))
 }
 
-func (s *KVStoreServer) unregister() error {
-	resp, err := http.Get(fmt.Sprintf("%s/unregister?host=%s", s.RegistryHost, s.Address))
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-	log.Printf("Unregister to %s", s.RegistryHost)
-	return nil
+// Get 实现transport.KVServer，供gRPC数据面调用
+func (s *KVStoreServer) Get(ctx context.Context, key string) (string, error) {
+	return s.getOrSet(key), nil
 }
-
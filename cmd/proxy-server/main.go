@@ -1,28 +1,91 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
 
 	"github.com/khighness/go-consistent-hashing/core"
 	"github.com/khighness/go-consistent-hashing/proxy"
+	"github.com/khighness/go-consistent-hashing/registry"
+	"github.com/khighness/go-consistent-hashing/transport"
 )
 
 // @Author KHighness
-// @Update 2022-06-24
+// @Update 2022-09-01
+
+// defaultReplicationFactor 未通过?n=指定副本数时使用的默认值
+const defaultReplicationFactor = 2
+
+var (
+	etcdEndpoints = flag.String("etcd", "127.0.0.1:2379", "comma separated etcd endpoints")
+)
+
+// balanceKinds 代理服务器同时对外提供的全部负载均衡策略
+var balanceKinds = []string{
+	core.RoundRobinBalance,
+	core.LeastConnectionsBalance,
+	core.ConsistentHashBalance,
+	core.ConsistentHashBoundedBalance,
+	core.JumpHashBalance,
+	core.MaglevBalance,
+}
 
 func main() {
+	flag.Parse()
+
+	reg, err := registry.NewEtcdRegistry(strings.Split(*etcdEndpoints, ","))
+	if err != nil {
+		panic(err)
+	}
+
+	// grpcTransport在proxy与kv-store之间维护保活的gRPC连接池，
+	// 所有策略的Proxy共享同一个transport实例
+	grpcTransport := transport.NewGRPCTransport(grpc.WithInsecure())
+
+	balancers := make(map[string]core.LoadBalancer, len(balanceKinds))
+	proxies := make(map[string]*proxy.Proxy, len(balanceKinds))
+	for _, kind := range balanceKinds {
+		lb, err := core.LoadBalanceFactory(kind)
+		if err != nil {
+			panic(err)
+		}
+		balancers[kind] = lb
+		proxies[kind] = proxy.NewProxy(lb, grpcTransport)
+	}
+
+	// 一致性哈希类策略的ring由etcd注册中心驱动，host上下线无需重启proxy即可生效；
+	// 轮询/最小连接数策略仍然依赖/register、/unregister手动维护
+	ctx := context.Background()
+	if chb, ok := balancers[core.ConsistentHashBalance].(*core.ConsistentHashBalancer); ok {
+		if err = registry.Sync(ctx, reg, chb.Underlying()); err != nil {
+			panic(err)
+		}
+		chb.Underlying().Subscribe(grpcTransport)
+	}
+	if chb, ok := balancers[core.ConsistentHashBoundedBalance].(*core.ConsistentHashBoundedBalancer); ok {
+		if err = registry.Sync(ctx, reg, chb.Underlying()); err != nil {
+			panic(err)
+		}
+		chb.Underlying().Subscribe(grpcTransport)
+	}
+
 	server := ProxyServer{
 		Address: "127.0.0.1:3333",
-		Proxy:   proxy.NewProxy(core.NewConsistent(10, nil)),
+		Proxies: proxies,
 	}
 	server.Start()
 }
 
 type ProxyServer struct {
 	Address string
-	Proxy *proxy.Proxy
+	Proxies map[string]*proxy.Proxy
 }
 
 func (s *ProxyServer) Start() {
@@ -31,17 +94,37 @@ func (s *ProxyServer) Start() {
 	http.HandleFunc("/register", s.registerHost)
 	http.HandleFunc("/unregister", s.unregisterHost)
 	http.HandleFunc("/key", s.getKey)
-	http.HandleFunc("/key_least", s.getKey)
+	http.HandleFunc("/key_replicated", s.getKeyReplicated)
 	if err := http.ListenAndServe(s.Address, nil); err != nil {
 		panic(err)
 	}
 }
 
+// pickProxy 根据?algo=选择对应负载均衡策略的代理，默认使用一致性哈希
+func (s *ProxyServer) pickProxy(r *http.Request) (*proxy.Proxy, error) {
+	algo := r.Form.Get("algo")
+	if algo == "" {
+		algo = core.ConsistentHashBalance
+	}
+	p, ok := s.Proxies[algo]
+	if !ok {
+		return nil, core.ErrUnknownBalanceKind
+	}
+	return p, nil
+}
+
 func (s *ProxyServer) getKey(w http.ResponseWriter, r *http.Request) {
 	_ = r.ParseForm()
 	key := r.Form["key"][0]
 
-	val, err := s.Proxy.GetKey(key)
+	p, err := s.pickProxy(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = fmt.Fprintf(w, err.Error())
+		return
+	}
+
+	val, err := p.GetKey(key)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		_, _ = fmt.Fprintf(w, err.Error())
@@ -50,11 +133,25 @@ func (s *ProxyServer) getKey(w http.ResponseWriter, r *http.Request) {
 	_, _ = fmt.Fprintf(w, val)
 }
 
-func (s *ProxyServer) getKeyLeast(w http.ResponseWriter, r *http.Request) {
+func (s *ProxyServer) getKeyReplicated(w http.ResponseWriter, r *http.Request) {
 	_ = r.ParseForm()
 	key := r.Form["key"][0]
 
-	val, err := s.Proxy.GetKeyLeast(key)
+	n := defaultReplicationFactor
+	if nStr := r.Form.Get("n"); nStr != "" {
+		if parsed, err := strconv.Atoi(nStr); err == nil && parsed > 0 {
+			n = parsed
+		}
+	}
+
+	p, err := s.pickProxy(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = fmt.Fprintf(w, err.Error())
+		return
+	}
+
+	val, err := p.GetKeyReplicated(key, n)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		_, _ = fmt.Fprintf(w, err.Error())
@@ -63,26 +160,62 @@ func (s *ProxyServer) getKeyLeast(w http.ResponseWriter, r *http.Request) {
 	_, _ = fmt.Fprintf(w, val)
 }
 
+// applyToAll 按balanceKinds的固定顺序把fn应用到每一策略的Proxy上；按固定顺序
+// 遍历而不是遍历s.Proxies这个map，且即使某一策略失败也会继续尝试其余策略，避免
+// 因为map遍历顺序的随机性导致不同请求间host在各策略上的状态不一致。ignorable
+// 中列出的错误被当作该策略下的预期结果（例如host已存在/不存在），不计入返回的
+// failed列表；其余错误会被各自log下来，一并返回给调用方，而不是在第一个错误处
+// 中断、让调用方无法得知哪些策略没生效
+func applyToAll(op, address string, fn func(kind string) error, ignorable ...error) (failed []string) {
+	for _, kind := range balanceKinds {
+		err := fn(kind)
+		if err == nil {
+			continue
+		}
+		ignored := false
+		for _, want := range ignorable {
+			if err == want {
+				ignored = true
+				break
+			}
+		}
+		if ignored {
+			log.Printf("%s: %s on %s skipped: %v", op, address, kind, err)
+			continue
+		}
+		log.Printf("%s: %s on %s failed: %v", op, address, kind, err)
+		failed = append(failed, fmt.Sprintf("%s: %v", kind, err))
+	}
+	return failed
+}
+
 func (s *ProxyServer) registerHost(w http.ResponseWriter, r *http.Request) {
 	_ = r.ParseForm()
 	address := r.Form["host"][0]
 
-	err := s.Proxy.RegisterHost(address)
-	if err != nil {
+	failed := applyToAll("registerHost", address, func(kind string) error {
+		return s.Proxies[kind].RegisterHost(address)
+	}, core.ErrHostAlreadyExists)
+	if len(failed) > 0 {
 		w.WriteHeader(http.StatusInternalServerError)
-		_, _ = fmt.Fprintf(w, err.Error())
-		return
+		_, _ = fmt.Fprintf(w, "failed to register on: %s", strings.Join(failed, "; "))
 	}
 }
 
+// unregisterHost 依次在balanceKinds的每一策略上注销该host。jump-hash只能摘除
+// 环上最后添加的host，对不满足该条件的摘除请求会返回core.ErrUnsupportedRemoval，
+// 这里把它当作该策略下可预期的跳过而非失败：否则jump-hash一旦拒绝，循环中排在
+// 它之后的其余策略就不会真正摘除该host，使得round-robin/least-connections/
+// consistent-hash(-bounded)继续把它当作在线节点路由请求
 func (s *ProxyServer) unregisterHost(w http.ResponseWriter, r *http.Request) {
 	_ = r.ParseForm()
 	address := r.Form["host"][0]
 
-	err := s.Proxy.UnregisterHost(address)
-	if err != nil {
+	failed := applyToAll("unregisterHost", address, func(kind string) error {
+		return s.Proxies[kind].UnregisterHost(address)
+	}, core.ErrHostNotFound, core.ErrUnsupportedRemoval)
+	if len(failed) > 0 {
 		w.WriteHeader(http.StatusInternalServerError)
-		_, _ = fmt.Fprintf(w, err.Error())
-		return
+		_, _ = fmt.Fprintf(w, "failed to unregister on: %s", strings.Join(failed, "; "))
 	}
-}
\ No newline at end of file
+}
@@ -12,21 +12,21 @@ import (
 )
 
 // @Author KHighness
-// @Update 2022-06-24
+// @Update 2022-08-20
 
 const (
 	// hostReplicaFormat 虚拟节点名称格式
 	hostReplicaFormat = "%s%d"
+
+	// defaultLoadBoundFactor 默认的负载边界因子
+	// ref: https://research.googleblog.com/2017/04/consistent-hashing-with-bounded-loads.html
+	defaultLoadBoundFactor = 0.25
 )
 
 var (
 	// defaultReplicaNum 虚拟节点数量
 	defaultReplicaNum = 10
 
-	// loadBoundFactor 负载边界因子
-	// ref: https://research.googleblog.com/2017/04/consistent-hashing-with-bounded-loads.html
-	loadBoundFactor = 0.25
-
 	// defaultHashFunc 默认哈希函数
 	defaultHashFunc = func(key string) uint64 {
 		out := sha512.Sum512([]byte(key))
@@ -34,6 +34,34 @@ var (
 	}
 )
 
+// Option 配置ConsistentHash的可选项
+type Option func(*ConsistentHash)
+
+// WithLoadBoundFactor 设置有界负载的边界因子，默认值为defaultLoadBoundFactor
+func WithLoadBoundFactor(factor float64) Option {
+	return func(ch *ConsistentHash) {
+		ch.loadBoundFactor = factor
+	}
+}
+
+// Observer 监听哈希环成员变化的回调接口，供registry等外部组件订阅，
+// 例如让gRPC连接池随host的增删自动建连/断连
+type Observer interface {
+	// OnHostAdded 在一台缓存服务器被注册后回调
+	OnHostAdded(address string)
+	// OnHostRemoved 在一台缓存服务器被注销后回调
+	OnHostRemoved(address string)
+}
+
+// ring 哈希环的不可变快照，通过atomic.Value发布，读路径(GetHostByKey)无需加锁
+type ring struct {
+	// replicaHostMap 映射表：虚拟节点index -> 缓存服务器address
+	replicaHostMap map[uint64]string
+
+	// sortedHostHashSet 哈希环
+	sortedHostHashSet []uint64
+}
+
 // ConsistentHash is an implementation of consistent-hashing-algorithm
 type ConsistentHash struct {
 	// replicaNum 缓存服务器在哈希环中对应的虚拟节点数
@@ -48,26 +76,28 @@ type ConsistentHash struct {
 	// hostMap 映射表：address -> Host
 	hostMap map[string]*Host
 
-	// replicaHostMap 映射表：虚拟节点index -> 缓存服务器address
-	replicaHostMap map[uint64]string
+	// observers 监听哈希环成员变化的回调
+	observers []Observer
 
-	// sortedHostHashSet 哈希环
-	sortedHostHashSet []uint64
+	// loadBoundFactor 负载边界因子，可通过WithLoadBoundFactor/SetLoadBoundFactor调整
+	loadBoundFactor float64
+
+	// ring 当前哈希环快照，读路径通过atomic.Load无锁访问
+	ring atomic.Value
 
-	// 读写锁
+	// 读写锁，保护hostMap及哈希环快照的写入
 	mu sync.RWMutex
 }
 
 // NewConsistent creates a consistent-hashing-algorithm
-func NewConsistent(replicaNum int, hashFunc func(key string) uint64) *ConsistentHash {
+func NewConsistent(replicaNum int, hashFunc func(key string) uint64, opts ...Option) *ConsistentHash {
 	ch := &ConsistentHash{
-		replicaNum:        replicaNum,
-		totalLoad:         0,
-		hashFunc:          hashFunc,
-		hostMap:           make(map[string]*Host),
-		replicaHostMap:    make(map[uint64]string),
-		sortedHostHashSet: make([]uint64, 0),
-		mu:                sync.RWMutex{},
+		replicaNum:      replicaNum,
+		totalLoad:       0,
+		hashFunc:        hashFunc,
+		hostMap:         make(map[string]*Host),
+		loadBoundFactor: defaultLoadBoundFactor,
+		mu:              sync.RWMutex{},
 	}
 
 	if ch.replicaNum <= 0 {
@@ -77,11 +107,57 @@ func NewConsistent(replicaNum int, hashFunc func(key string) uint64) *Consistent
 		ch.hashFunc = defaultHashFunc
 	}
 
+	for _, opt := range opts {
+		opt(ch)
+	}
+
+	ch.ring.Store(&ring{
+		replicaHostMap:    make(map[uint64]string),
+		sortedHostHashSet: make([]uint64, 0),
+	})
+
 	return ch
 }
 
-// RegisterHost 注册缓存服务器
+// SetLoadBoundFactor 运行时调整有界负载的边界因子
+func (ch *ConsistentHash) SetLoadBoundFactor(factor float64) {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	ch.loadBoundFactor = factor
+}
+
+// Subscribe 注册一个Observer，在哈希环成员变化时收到通知
+func (ch *ConsistentHash) Subscribe(observer Observer) {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	ch.observers = append(ch.observers, observer)
+}
+
+func (ch *ConsistentHash) notifyHostAdded(address string) {
+	for _, observer := range ch.observers {
+		observer.OnHostAdded(address)
+	}
+}
+
+func (ch *ConsistentHash) notifyHostRemoved(address string) {
+	for _, observer := range ch.observers {
+		observer.OnHostRemoved(address)
+	}
+}
+
+// loadRing 无锁读取当前哈希环快照
+func (ch *ConsistentHash) loadRing() *ring {
+	return ch.ring.Load().(*ring)
+}
+
+// RegisterHost 注册缓存服务器，使用默认权重1，即虚拟节点数等于replicaNum
 func (ch *ConsistentHash) RegisterHost(address string) error {
+	return ch.RegisterHostWithWeight(address, 1)
+}
+
+// RegisterHostWithWeight 按权重注册缓存服务器，weight越大在哈希环上获得的虚拟节点
+// (replicaNum*weight)越多，也会在有界负载检查中分摊到更高比例的总负载
+func (ch *ConsistentHash) RegisterHostWithWeight(address string, weight int) error {
 	ch.mu.Lock()
 	defer ch.mu.Unlock()
 
@@ -89,21 +165,35 @@ func (ch *ConsistentHash) RegisterHost(address string) error {
 	if _, ok := ch.hostMap[address]; ok {
 		return ErrHostAlreadyExists
 	}
-	log.Printf("Register host: %s", address)
-	ch.hostMap[address] = &Host{Address: address, LoadBound: 0}
+	if weight <= 0 {
+		weight = 1
+	}
+	log.Printf("Register host: %s, weight: %d", address, weight)
+	ch.hostMap[address] = &Host{Address: address, Weight: weight, LoadBound: 0}
+
+	// 拷贝出一份新的哈希环快照，在其上增加虚拟节点后整体替换，读路径不受影响
+	replicas := ch.replicaNum * weight
+	old := ch.loadRing()
+	replicaHostMap := make(map[uint64]string, len(old.replicaHostMap)+replicas)
+	for k, v := range old.replicaHostMap {
+		replicaHostMap[k] = v
+	}
+	sortedHostHashSet := make([]uint64, len(old.sortedHostHashSet))
+	copy(sortedHostHashSet, old.sortedHostHashSet)
 
-	// 循环创建虚拟节点
-	for i := 0; i < ch.replicaNum; i++ {
+	for i := 0; i < replicas; i++ {
 		hashedIdx := ch.hashFunc(fmt.Sprintf(hostReplicaFormat, address, i))
 		log.Printf("Add virtual node %v for host %s", hashedIdx, address)
-		ch.replicaHostMap[hashedIdx] = address
-		ch.sortedHostHashSet = append(ch.sortedHostHashSet, hashedIdx)
+		replicaHostMap[hashedIdx] = address
+		sortedHostHashSet = append(sortedHostHashSet, hashedIdx)
 	}
 
-	// 对哈希环排序
-	sort.Slice(ch.sortedHostHashSet, func(i, j int) bool {
-		return ch.sortedHostHashSet[i] < ch.sortedHostHashSet[j]
+	sort.Slice(sortedHostHashSet, func(i, j int) bool {
+		return sortedHostHashSet[i] < sortedHostHashSet[j]
 	})
+
+	ch.ring.Store(&ring{replicaHostMap: replicaHostMap, sortedHostHashSet: sortedHostHashSet})
+	ch.notifyHostAdded(address)
 	return nil
 }
 
@@ -112,58 +202,112 @@ func (ch *ConsistentHash) UnregisterHost(address string) error {
 	ch.mu.Lock()
 	defer ch.mu.Unlock()
 
-	// 怕暖服务器是否存在
-	if _, ok := ch.hostMap[address]; !ok {
+	// 判断服务器是否存在
+	host, ok := ch.hostMap[address]
+	if !ok {
 		return ErrHostNotFound
 	}
 	log.Printf("Unregister host: %s", address)
 	delete(ch.hostMap, address)
 
-	// 循环删除虚拟节点
-	for i := 0; i < ch.replicaNum; i++ {
+	old := ch.loadRing()
+	replicaHostMap := make(map[uint64]string, len(old.replicaHostMap))
+	for k, v := range old.replicaHostMap {
+		replicaHostMap[k] = v
+	}
+	sortedHostHashSet := make([]uint64, len(old.sortedHostHashSet))
+	copy(sortedHostHashSet, old.sortedHostHashSet)
+
+	replicas := ch.replicaNum * host.Weight
+	for i := 0; i < replicas; i++ {
 		hashedIdx := ch.hashFunc(fmt.Sprintf(hostReplicaFormat, address, i))
 		log.Printf("Remove virtual node %v for host %s", hashedIdx, address)
-		delete(ch.replicaHostMap, hashedIdx)
-		ch.delHashIndex(hashedIdx)
+		delete(replicaHostMap, hashedIdx)
+		sortedHostHashSet = delHashIndex(sortedHostHashSet, hashedIdx)
 	}
+
+	ch.ring.Store(&ring{replicaHostMap: replicaHostMap, sortedHostHashSet: sortedHostHashSet})
+	ch.notifyHostRemoved(address)
 	return nil
 }
 
-// delHashIndex 从哈希环中移除虚拟节点
-func (ch *ConsistentHash) delHashIndex(val uint64) {
+// delHashIndex 从哈希环快照中移除虚拟节点，返回移除后的切片
+func delHashIndex(sortedHostHashSet []uint64, val uint64) []uint64 {
 	idx := -1
 	l := 0
-	r := len(ch.sortedHostHashSet) - 1
+	r := len(sortedHostHashSet) - 1
 	for l <= r {
 		m := (l + r) / 2
-		if ch.sortedHostHashSet[m] == val {
+		if sortedHostHashSet[m] == val {
 			idx = m
 			break
-		} else if ch.sortedHostHashSet[m] < val {
+		} else if sortedHostHashSet[m] < val {
 			l = m + 1
 		} else {
 			r = m - 1
 		}
 	}
 	if idx != -1 {
-		ch.sortedHostHashSet = append(ch.sortedHostHashSet[:idx], ch.sortedHostHashSet[idx+1:]...)
+		sortedHostHashSet = append(sortedHostHashSet[:idx], sortedHostHashSet[idx+1:]...)
 	}
+	return sortedHostHashSet
 }
 
-// GetKey 根据Key查询Host
+// GetHostByKey 根据Key查询Host，读路径无锁，只依赖atomic.Value发布的快照
 func (ch *ConsistentHash) GetHostByKey(key string) (string, error) {
+	snapshot := ch.loadRing()
+	if len(snapshot.sortedHostHashSet) == 0 {
+		return "", ErrHostNotFound
+	}
+
 	hashedIdx := ch.hashFunc(key)
-	idx := ch.searchIndex(hashedIdx)
-	return ch.replicaHostMap[ch.sortedHostHashSet[idx]], nil
+	idx := searchIndex(snapshot.sortedHostHashSet, hashedIdx)
+	return snapshot.replicaHostMap[snapshot.sortedHostHashSet[idx]], nil
+}
+
+// GetHostsByKey 从key的哈希位置沿环顺时针查找，返回前n个不重复的物理主机，
+// 跳过虚拟节点造成的重复，为副本读、故障转移和缓存预热提供基础能力
+func (ch *ConsistentHash) GetHostsByKey(key string, n int) ([]string, error) {
+	snapshot := ch.loadRing()
+	if len(snapshot.sortedHostHashSet) == 0 {
+		return nil, ErrHostNotFound
+	}
+	if n <= 0 {
+		return nil, nil
+	}
+
+	ch.mu.RLock()
+	hostCount := len(ch.hostMap)
+	ch.mu.RUnlock()
+	if n > hostCount {
+		n = hostCount
+	}
+
+	hashedIdx := ch.hashFunc(key)
+	idx := searchIndex(snapshot.sortedHostHashSet, hashedIdx)
+
+	seen := make(map[string]struct{}, n)
+	hosts := make([]string, 0, n)
+	ringLen := len(snapshot.sortedHostHashSet)
+	for i := 0; i < ringLen && len(hosts) < n; i++ {
+		pos := (idx + i) % ringLen
+		address := snapshot.replicaHostMap[snapshot.sortedHostHashSet[pos]]
+		if _, ok := seen[address]; ok {
+			continue
+		}
+		seen[address] = struct{}{}
+		hosts = append(hosts, address)
+	}
+	return hosts, nil
 }
 
 // searchIndex 根据key在哈希环上顺指针寻找第一台缓存服务器的索引
-func (ch *ConsistentHash) searchIndex(key uint64) int {
-	idx := sort.Search(len(ch.sortedHostHashSet), func(i int) bool {
-		return ch.sortedHostHashSet[i] >= key
+func searchIndex(sortedHostHashSet []uint64, key uint64) int {
+	idx := sort.Search(len(sortedHostHashSet), func(i int) bool {
+		return sortedHostHashSet[i] >= key
 	})
 
-	if idx >= len(ch.sortedHostHashSet) {
+	if idx >= len(sortedHostHashSet) {
 		idx = 0
 	}
 	return idx
@@ -172,18 +316,20 @@ func (ch *ConsistentHash) searchIndex(key uint64) int {
 // GetHostByKeyLeast 有界负载的一致性哈希
 func (ch *ConsistentHash) GetHostByKeyLeast(key string) (string, error) {
 	ch.mu.RLock()
-	defer ch.mu.RLock()
+	defer ch.mu.RUnlock()
 
-	if len(ch.replicaHostMap) == 0 {
+	snapshot := ch.loadRing()
+	if len(snapshot.replicaHostMap) == 0 {
 		return "", ErrHostNotFound
 	}
 
 	hashedIdx := ch.hashFunc(key)
-	idx := ch.searchIndex(hashedIdx)
+	idx := searchIndex(snapshot.sortedHostHashSet, hashedIdx)
 
+	ringLen := len(snapshot.sortedHostHashSet)
 	i := idx
-	for {
-		address := ch.replicaHostMap[ch.sortedHostHashSet[i]]
+	for scanned := 0; scanned < ringLen; scanned++ {
+		address := snapshot.replicaHostMap[snapshot.sortedHostHashSet[i]]
 		loadChecked, err := ch.checkLoadCapacity(address)
 		if err != nil {
 			return "", err
@@ -193,26 +339,52 @@ func (ch *ConsistentHash) GetHostByKeyLeast(key string) (string, error) {
 		}
 		i++
 
-		if i >= len(ch.replicaHostMap) {
+		if i >= ringLen {
 			i = 0
 		}
 	}
+
+	// 扫完整个环仍没有未超载的主机
+	return "", ErrAllHostsOverloaded
 }
 
-// MaxLoad 获取单节点的最大负载
-// (total_load / number_of_hosts) * (1 + load_bound_factor)
-func (ch *ConsistentHash) MaxLoad() int64 {
-	if ch.totalLoad == 0 {
-		ch.totalLoad = 1
+// totalWeight 返回所有已注册主机的权重之和，调用方需持有mu
+func (ch *ConsistentHash) totalWeight() int64 {
+	var sum int64
+	for _, host := range ch.hostMap {
+		sum += int64(host.Weight)
 	}
+	return sum
+}
 
-	var avgLoadPerNode float64
-	avgLoadPerNode = float64(ch.totalLoad / int64(len(ch.hostMap)))
+// MaxLoad 获取指定主机的最大负载，按该主机的权重占比分摊总负载，而不是
+// 简单地除以主机数，使得权重更高的主机能够获得成比例更高的负载上限
+// ceil((total_load * weight / total_weight) * (1 + load_bound_factor))
+func (ch *ConsistentHash) MaxLoad(address string) (int64, error) {
+	ch.mu.RLock()
+	defer ch.mu.RUnlock()
+
+	host, ok := ch.hostMap[address]
+	if !ok {
+		return 0, ErrHostNotFound
+	}
+
+	totalLoad := atomic.LoadInt64(&ch.totalLoad)
+	if totalLoad == 0 {
+		totalLoad = 1
+	}
+
+	totalWeight := ch.totalWeight()
+	if totalWeight == 0 {
+		totalWeight = 1
+	}
+
+	avgLoadPerNode := float64(totalLoad) * float64(host.Weight) / float64(totalWeight)
 	if avgLoadPerNode == 0 {
 		avgLoadPerNode = 1
 	}
-	avgLoadPerNode = math.Ceil(avgLoadPerNode * (1 + loadBoundFactor))
-	return int64(avgLoadPerNode)
+	avgLoadPerNode = math.Ceil(avgLoadPerNode * (1 + ch.loadBoundFactor))
+	return int64(avgLoadPerNode), nil
 }
 
 // IncLoad 递增缓存服务器的负载
@@ -245,24 +417,38 @@ func (ch *ConsistentHash) GetLoads() map[string]int64 {
 	return loads
 }
 
-// checkLoadCapacity 检验一个缓存服务器是否能在有界负载之内提供服务
+// checkLoadCapacity 检验一个缓存服务器是否能在有界负载之内提供服务，
+// 负载上限按该主机的权重占比分摊总负载，而不是简单地除以主机数；
+// GetHostByKeyLeast的调用方只持有ch.mu的RLock，因此本函数会被多个
+// goroutine并发调用，对ch.totalLoad的钳位必须用CAS完成，普通读写会data race
 func (ch *ConsistentHash) checkLoadCapacity(address string) (bool, error) {
-	if ch.totalLoad < 0 {
-		ch.totalLoad = 0
-	}
-
-	var avgLoadPerNode float64
-	avgLoadPerNode = float64((ch.totalLoad + 1) / int64(len(ch.hostMap)))
-	if avgLoadPerNode == 0 {
-		avgLoadPerNode = 1
+	for {
+		cur := atomic.LoadInt64(&ch.totalLoad)
+		if cur >= 0 {
+			break
+		}
+		if atomic.CompareAndSwapInt64(&ch.totalLoad, cur, 0) {
+			break
+		}
 	}
-	avgLoadPerNode = math.Ceil(avgLoadPerNode * (1 + loadBoundFactor))
 
 	candidateHost, ok := ch.hostMap[address]
 	if !ok {
 		return false, ErrHostNotFound
 	}
 
+	totalWeight := ch.totalWeight()
+	if totalWeight == 0 {
+		totalWeight = 1
+	}
+
+	totalLoad := atomic.LoadInt64(&ch.totalLoad)
+	avgLoadPerNode := float64(totalLoad+1) * float64(candidateHost.Weight) / float64(totalWeight)
+	if avgLoadPerNode == 0 {
+		avgLoadPerNode = 1
+	}
+	avgLoadPerNode = math.Ceil(avgLoadPerNode * (1 + ch.loadBoundFactor))
+
 	if float64(candidateHost.LoadBound)+1 <= avgLoadPerNode {
 		return true, nil
 	}
@@ -0,0 +1,86 @@
+package core
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// @Author KHighness
+// @Update 2022-08-20
+
+// TestBoundedLoadRespectsFormulaAcrossEpsilonAndHostCounts验证有界负载对不同的
+// loadBoundFactor(ε)和host数量，都能把每台host的负载限制在MaxLoad给出的上限内
+func TestBoundedLoadRespectsFormulaAcrossEpsilonAndHostCounts(t *testing.T) {
+	epsilons := []float64{0, 0.1, 0.25, 0.5, 1}
+	hostCounts := []int{2, 3, 5, 8}
+
+	for _, epsilon := range epsilons {
+		for _, hostCount := range hostCounts {
+			epsilon, hostCount := epsilon, hostCount
+			t.Run(fmt.Sprintf("epsilon=%.2f/hosts=%d", epsilon, hostCount), func(t *testing.T) {
+				ch := NewConsistent(defaultReplicaNum, nil, WithLoadBoundFactor(epsilon))
+				hosts := make([]string, hostCount)
+				for i := range hosts {
+					hosts[i] = fmt.Sprintf("127.0.0.1:%d", 10000+i)
+					if err := ch.RegisterHost(hosts[i]); err != nil {
+						t.Fatalf("RegisterHost(%s): %v", hosts[i], err)
+					}
+				}
+
+				for i := 0; i < 2000; i++ {
+					host, err := ch.GetHostByKeyLeast(fmt.Sprintf("key-%d", i))
+					if err != nil {
+						if err == ErrAllHostsOverloaded {
+							// 所有host都已到达上限是合法的终止状态
+							break
+						}
+						t.Fatalf("GetHostByKeyLeast: %v", err)
+					}
+					ch.IncLoad(host)
+
+					maxLoad, err := ch.MaxLoad(host)
+					if err != nil {
+						t.Fatalf("MaxLoad(%s): %v", host, err)
+					}
+					if load := ch.GetLoads()[host]; load > maxLoad {
+						t.Fatalf("host %s load %d exceeds bound %d (epsilon=%.2f, hosts=%d)",
+							host, load, maxLoad, epsilon, hostCount)
+					}
+				}
+			})
+		}
+	}
+}
+
+// TestGetHostByKeyLeastConcurrentNoRace并发调用GetHostByKeyLeast(只持有ch.mu的RLock)，
+// 用于在go test -race下复现并守护checkLoadCapacity里对ch.totalLoad的钳位曾经
+// 存在的data race：先用多余的DecLoad把totalLoad打到负数，让并发的GetHostByKeyLeast
+// 同时触发钳位分支，并发写同一个非atomic字段
+func TestGetHostByKeyLeastConcurrentNoRace(t *testing.T) {
+	ch := NewConsistent(defaultReplicaNum, nil)
+	const host = "127.0.0.1:10000"
+	if err := ch.RegisterHost(host); err != nil {
+		t.Fatalf("RegisterHost: %v", err)
+	}
+	for i := 0; i < 1000; i++ {
+		ch.DecLoad(host)
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 32; g++ {
+		g := g
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				ch.DecLoad(host)
+				if _, err := ch.GetHostByKeyLeast(fmt.Sprintf("g%d-key-%d", g, i)); err != nil && err != ErrAllHostsOverloaded {
+					t.Errorf("GetHostByKeyLeast: %v", err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
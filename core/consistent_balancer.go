@@ -0,0 +1,100 @@
+package core
+
+// @Author KHighness
+// @Update 2022-08-20
+
+// ConsistentHashBalancer 把ConsistentHash适配成LoadBalancer，选址走普通的哈希环查找
+type ConsistentHashBalancer struct {
+	ch *ConsistentHash
+}
+
+// NewConsistentHashBalancer 创建一个基于普通一致性哈希的LoadBalancer
+func NewConsistentHashBalancer(replicaNum int, hashFunc func(key string) uint64) *ConsistentHashBalancer {
+	return &ConsistentHashBalancer{ch: NewConsistent(replicaNum, hashFunc)}
+}
+
+func (b *ConsistentHashBalancer) Pick(key string) (string, error) {
+	return b.ch.GetHostByKey(key)
+}
+
+func (b *ConsistentHashBalancer) RegisterHost(address string) error {
+	return b.ch.RegisterHost(address)
+}
+
+func (b *ConsistentHashBalancer) UnregisterHost(address string) error {
+	return b.ch.UnregisterHost(address)
+}
+
+func (b *ConsistentHashBalancer) IncLoad(address string) {
+	b.ch.IncLoad(address)
+}
+
+func (b *ConsistentHashBalancer) DecLoad(address string) {
+	b.ch.DecLoad(address)
+}
+
+// RegisterHostWithWeight 按权重注册主机，仅一致性哈希类策略支持
+func (b *ConsistentHashBalancer) RegisterHostWithWeight(address string, weight int) error {
+	return b.ch.RegisterHostWithWeight(address, weight)
+}
+
+// PickN 实现Replicator，返回key在哈希环上的前n个不重复主机
+func (b *ConsistentHashBalancer) PickN(key string, n int) ([]string, error) {
+	return b.ch.GetHostsByKey(key, n)
+}
+
+// Underlying 返回底层的ConsistentHash，供registry等基础设施订阅Observer事件
+func (b *ConsistentHashBalancer) Underlying() *ConsistentHash {
+	return b.ch
+}
+
+// ConsistentHashBoundedBalancer 把ConsistentHash适配成LoadBalancer，选址走有界负载的哈希环查找
+type ConsistentHashBoundedBalancer struct {
+	ch *ConsistentHash
+}
+
+// NewConsistentHashBoundedBalancer 创建一个基于有界负载一致性哈希的LoadBalancer，
+// opts可用于通过WithLoadBoundFactor调整负载边界因子
+func NewConsistentHashBoundedBalancer(replicaNum int, hashFunc func(key string) uint64, opts ...Option) *ConsistentHashBoundedBalancer {
+	return &ConsistentHashBoundedBalancer{ch: NewConsistent(replicaNum, hashFunc, opts...)}
+}
+
+func (b *ConsistentHashBoundedBalancer) Pick(key string) (string, error) {
+	return b.ch.GetHostByKeyLeast(key)
+}
+
+func (b *ConsistentHashBoundedBalancer) RegisterHost(address string) error {
+	return b.ch.RegisterHost(address)
+}
+
+func (b *ConsistentHashBoundedBalancer) UnregisterHost(address string) error {
+	return b.ch.UnregisterHost(address)
+}
+
+func (b *ConsistentHashBoundedBalancer) IncLoad(address string) {
+	b.ch.IncLoad(address)
+}
+
+func (b *ConsistentHashBoundedBalancer) DecLoad(address string) {
+	b.ch.DecLoad(address)
+}
+
+// RegisterHostWithWeight 按权重注册主机，仅一致性哈希类策略支持
+func (b *ConsistentHashBoundedBalancer) RegisterHostWithWeight(address string, weight int) error {
+	return b.ch.RegisterHostWithWeight(address, weight)
+}
+
+// PickN 实现Replicator，返回key在哈希环上的前n个不重复主机
+func (b *ConsistentHashBoundedBalancer) PickN(key string, n int) ([]string, error) {
+	return b.ch.GetHostsByKey(key, n)
+}
+
+// SetLoadBoundFactor 运行时调整有界负载的边界因子
+func (b *ConsistentHashBoundedBalancer) SetLoadBoundFactor(factor float64) {
+	b.ch.SetLoadBoundFactor(factor)
+}
+
+// Underlying 返回底层的ConsistentHash，供registry等基础设施订阅Observer事件
+func (b *ConsistentHashBoundedBalancer) Underlying() *ConsistentHash {
+	return b.ch
+}
@@ -6,7 +6,12 @@ import "errors"
 // @Update 2022-06-24
 
 var (
-	ErrHostAlreadyExists = errors.New("host already exists")
-	ErrHostNotFound      = errors.New("host not found")
+	ErrHostAlreadyExists      = errors.New("host already exists")
+	ErrHostNotFound           = errors.New("host not found")
+	ErrUnknownBalanceKind     = errors.New("unknown load balance kind")
+	ErrReplicationUnsupported = errors.New("load balancer does not support replicated reads")
+	ErrAllHostsFailed         = errors.New("all replicated hosts failed")
+	ErrUnsupportedRemoval     = errors.New("removing a host from the middle of the ring is not supported")
+	ErrAllHostsOverloaded     = errors.New("all hosts are over the load bound")
 )
 
@@ -1,11 +1,16 @@
 package core
 
 // @Author KHighness
-// @Update 2022-06-24
+// @Update 2022-07-25
 
 type Host struct {
 	// Address host:port
 	Address string
+	// Weight 权重，决定该主机在哈希环上获得的虚拟节点倍数(replicaNum*Weight)，
+	// 以及在有界负载检查中分摊到的总负载比例
+	Weight int
+	// Capacity 主机的容量上限，可选，供上层结合Weight做更精细的容量规划
+	Capacity int64
 	// LoadBound 缓存服务器当前处理的请求缓存数
 	LoadBound int64
-}
\ No newline at end of file
+}
@@ -0,0 +1,79 @@
+package core
+
+import "sync"
+
+// @Author KHighness
+// @Update 2022-08-14
+
+// JumpHash 是Google jump consistent hash算法的LoadBalancer实现，只需O(1)内存、
+// 约O(log n)时间即可完成选址，但依赖稳定的host顺序，因此只支持在尾部追加/弹出，
+// 移除中间的host会打乱后续所有桶的归属
+type JumpHash struct {
+	hosts []string
+	mu    sync.RWMutex
+}
+
+// NewJumpHash 创建一个JumpHash负载均衡器
+func NewJumpHash(hosts []string) *JumpHash {
+	jh := &JumpHash{hosts: make([]string, len(hosts))}
+	copy(jh.hosts, hosts)
+	return jh
+}
+
+// jumpConsistentHash 计算key落在[0,numBuckets)个桶中的哪一个
+// ref: https://arxiv.org/abs/1406.2294
+func jumpConsistentHash(key uint64, numBuckets int) int32 {
+	var b, j int64 = -1, 0
+	for j < int64(numBuckets) {
+		b = j
+		key = key*2862933555777941757 + 1
+		j = int64(float64(b+1) * (float64(int64(1)<<31) / float64((key>>33)+1)))
+	}
+	return int32(b)
+}
+
+func (jh *JumpHash) Pick(key string) (string, error) {
+	jh.mu.RLock()
+	defer jh.mu.RUnlock()
+
+	if len(jh.hosts) == 0 {
+		return "", ErrHostNotFound
+	}
+	idx := jumpConsistentHash(defaultHashFunc(key), len(jh.hosts))
+	return jh.hosts[idx], nil
+}
+
+// RegisterHost 只支持在尾部追加，因为jump hash依赖稳定的桶序
+func (jh *JumpHash) RegisterHost(address string) error {
+	jh.mu.Lock()
+	defer jh.mu.Unlock()
+
+	for _, host := range jh.hosts {
+		if host == address {
+			return ErrHostAlreadyExists
+		}
+	}
+	jh.hosts = append(jh.hosts, address)
+	return nil
+}
+
+// UnregisterHost 只支持移除末尾的host，移除中间的host会导致大规模重分布
+func (jh *JumpHash) UnregisterHost(address string) error {
+	jh.mu.Lock()
+	defer jh.mu.Unlock()
+
+	if len(jh.hosts) == 0 {
+		return ErrHostNotFound
+	}
+	if jh.hosts[len(jh.hosts)-1] != address {
+		return ErrUnsupportedRemoval
+	}
+	jh.hosts = jh.hosts[:len(jh.hosts)-1]
+	return nil
+}
+
+// IncLoad jump hash不维护每个host的负载，空实现
+func (jh *JumpHash) IncLoad(address string) {}
+
+// DecLoad jump hash不维护每个host的负载，空实现
+func (jh *JumpHash) DecLoad(address string) {}
@@ -0,0 +1,70 @@
+package core
+
+import "sync"
+
+// @Author KHighness
+// @Update 2022-07-02
+
+// LeastConnections 最小连接数负载均衡，总是选择当前负载最低的缓存服务器
+type LeastConnections struct {
+	loads map[string]int64
+	mu    sync.Mutex
+}
+
+// NewLeastConnections 创建一个LeastConnections负载均衡器
+func NewLeastConnections() *LeastConnections {
+	return &LeastConnections{loads: make(map[string]int64)}
+}
+
+func (lc *LeastConnections) Pick(key string) (string, error) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	if len(lc.loads) == 0 {
+		return "", ErrHostNotFound
+	}
+
+	var best string
+	var bestLoad int64 = -1
+	for host, load := range lc.loads {
+		if bestLoad == -1 || load < bestLoad {
+			best = host
+			bestLoad = load
+		}
+	}
+	return best, nil
+}
+
+func (lc *LeastConnections) RegisterHost(address string) error {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	if _, ok := lc.loads[address]; ok {
+		return ErrHostAlreadyExists
+	}
+	lc.loads[address] = 0
+	return nil
+}
+
+func (lc *LeastConnections) UnregisterHost(address string) error {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+
+	if _, ok := lc.loads[address]; !ok {
+		return ErrHostNotFound
+	}
+	delete(lc.loads, address)
+	return nil
+}
+
+func (lc *LeastConnections) IncLoad(address string) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	lc.loads[address]++
+}
+
+func (lc *LeastConnections) DecLoad(address string) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	lc.loads[address]--
+}
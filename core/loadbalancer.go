@@ -0,0 +1,56 @@
+package core
+
+// @Author KHighness
+// @Update 2022-07-02
+
+// 支持的负载均衡策略名称，供LoadBalanceFactory识别
+const (
+	RoundRobinBalance            = "round_robin"
+	LeastConnectionsBalance      = "least_connections"
+	ConsistentHashBalance        = "consistent_hash"
+	ConsistentHashBoundedBalance = "consistent_hash_bounded"
+	JumpHashBalance              = "jump_hash"
+	MaglevBalance                = "maglev"
+)
+
+// LoadBalancer 屏蔽不同选址策略（轮询、最小连接数、一致性哈希...）的差异，
+// 是proxy.Proxy真正依赖的选址抽象
+type LoadBalancer interface {
+	// Pick 根据key选出一台缓存服务器
+	Pick(key string) (string, error)
+	// RegisterHost 注册缓存服务器
+	RegisterHost(address string) error
+	// UnregisterHost 注销缓存服务器
+	UnregisterHost(address string) error
+	// IncLoad 递增缓存服务器的负载
+	IncLoad(address string)
+	// DecLoad 递减缓存服务器的负载
+	DecLoad(address string)
+}
+
+// Replicator 是LoadBalancer的可选扩展接口，允许为一个key返回多个不同的候选主机，
+// 仅一致性哈希类策略实现，用于故障转移与读修复
+type Replicator interface {
+	// PickN 返回key对应的前n个不重复的候选主机
+	PickN(key string, n int) ([]string, error)
+}
+
+// LoadBalanceFactory 根据策略名称创建对应的LoadBalancer
+func LoadBalanceFactory(kind string) (LoadBalancer, error) {
+	switch kind {
+	case RoundRobinBalance:
+		return NewRoundRobin(), nil
+	case LeastConnectionsBalance:
+		return NewLeastConnections(), nil
+	case ConsistentHashBalance:
+		return NewConsistentHashBalancer(defaultReplicaNum, nil), nil
+	case ConsistentHashBoundedBalance:
+		return NewConsistentHashBoundedBalancer(defaultReplicaNum, nil), nil
+	case JumpHashBalance:
+		return NewJumpHash(nil), nil
+	case MaglevBalance:
+		return NewMaglev(nil, 0), nil
+	default:
+		return nil, ErrUnknownBalanceKind
+	}
+}
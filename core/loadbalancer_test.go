@@ -0,0 +1,126 @@
+package core
+
+import (
+	"fmt"
+	"testing"
+)
+
+// @Author KHighness
+// @Update 2022-08-20
+
+// balancerKinds覆盖LoadBalanceFactory支持的全部策略，供下面的共享测试套件遍历
+var balancerKinds = []string{
+	RoundRobinBalance,
+	LeastConnectionsBalance,
+	ConsistentHashBalance,
+	ConsistentHashBoundedBalance,
+	JumpHashBalance,
+	MaglevBalance,
+}
+
+// newTestBalancer创建一个kind对应的LoadBalancer并依次注册hosts
+func newTestBalancer(t *testing.T, kind string, hosts []string) LoadBalancer {
+	t.Helper()
+	lb, err := LoadBalanceFactory(kind)
+	if err != nil {
+		t.Fatalf("LoadBalanceFactory(%s): %v", kind, err)
+	}
+	for _, host := range hosts {
+		if err := lb.RegisterHost(host); err != nil {
+			t.Fatalf("RegisterHost(%s) on %s: %v", host, kind, err)
+		}
+	}
+	return lb
+}
+
+// TestLoadBalancerKeyDistribution验证每种策略在大量不同key下都会用到全部已注册的host，
+// 而不是把流量集中到其中一台
+func TestLoadBalancerKeyDistribution(t *testing.T) {
+	hosts := []string{"127.0.0.1:10000", "127.0.0.1:10001", "127.0.0.1:10002", "127.0.0.1:10003"}
+	const numKeys = 2000
+
+	for _, kind := range balancerKinds {
+		kind := kind
+		t.Run(kind, func(t *testing.T) {
+			lb := newTestBalancer(t, kind, hosts)
+
+			seen := make(map[string]int, len(hosts))
+			for i := 0; i < numKeys; i++ {
+				key := fmt.Sprintf("key-%d", i)
+				host, err := lb.Pick(key)
+				if err != nil {
+					t.Fatalf("Pick(%q): %v", key, err)
+				}
+				lb.IncLoad(host)
+				seen[host]++
+				lb.DecLoad(host)
+			}
+
+			for _, host := range hosts {
+				if seen[host] == 0 {
+					t.Errorf("%s: host %s was never selected across %d keys", kind, host, numKeys)
+				}
+			}
+		})
+	}
+}
+
+// TestLoadBalancerFailoverOnHostRemoval验证移除一台host之后，Pick既不会报错，
+// 也不会再把流量派发给已经下线的host
+func TestLoadBalancerFailoverOnHostRemoval(t *testing.T) {
+	hosts := []string{"127.0.0.1:10000", "127.0.0.1:10001", "127.0.0.1:10002"}
+
+	for _, kind := range balancerKinds {
+		kind := kind
+		t.Run(kind, func(t *testing.T) {
+			lb := newTestBalancer(t, kind, hosts)
+
+			// JumpHash只支持移除末尾的host，其余策略对移除顺序没有要求，
+			// 统一移除末尾的host以便共享同一套断言
+			removed := hosts[len(hosts)-1]
+			if err := lb.UnregisterHost(removed); err != nil {
+				t.Fatalf("UnregisterHost(%s): %v", removed, err)
+			}
+
+			for i := 0; i < 100; i++ {
+				key := fmt.Sprintf("key-%d", i)
+				host, err := lb.Pick(key)
+				if err != nil {
+					t.Fatalf("Pick(%q) after removing %s: %v", key, removed, err)
+				}
+				if host == removed {
+					t.Fatalf("Pick(%q) still routed to removed host %s", key, removed)
+				}
+			}
+		})
+	}
+}
+
+// TestConsistentHashBoundedLoadRespectsBound验证有界负载策略在持续选址的过程中，
+// 任意host的负载都不会超过MaxLoad给出的上限
+func TestConsistentHashBoundedLoadRespectsBound(t *testing.T) {
+	balancer := NewConsistentHashBoundedBalancer(defaultReplicaNum, nil)
+	hosts := []string{"127.0.0.1:10000", "127.0.0.1:10001"}
+	for _, host := range hosts {
+		if err := balancer.RegisterHost(host); err != nil {
+			t.Fatalf("RegisterHost(%s): %v", host, err)
+		}
+	}
+
+	for i := 0; i < 500; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		host, err := balancer.Pick(key)
+		if err != nil {
+			t.Fatalf("Pick(%q): %v", key, err)
+		}
+		balancer.IncLoad(host)
+
+		maxLoad, err := balancer.Underlying().MaxLoad(host)
+		if err != nil {
+			t.Fatalf("MaxLoad(%s): %v", host, err)
+		}
+		if load := balancer.Underlying().GetLoads()[host]; load > maxLoad {
+			t.Fatalf("host %s load %d exceeds bound %d after pick %d", host, load, maxLoad, i)
+		}
+	}
+}
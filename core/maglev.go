@@ -0,0 +1,124 @@
+package core
+
+import "sync"
+
+// @Author KHighness
+// @Update 2022-08-14
+
+// defaultMaglevTableSize 默认查找表大小，需为质数，沿用论文推荐的65537
+const defaultMaglevTableSize = 65537
+
+// Maglev 是Google Maglev论文中查找表负载均衡算法的LoadBalancer实现。
+// 查找表构建需要O(M)，但构建完成后每次查找只需O(1)的表索引，
+// 并且在host集合小范围变动时具有良好的抗扰动性(table中大部分槽位归属不变)
+type Maglev struct {
+	tableSize int
+	hosts     []string
+	lookup    []int // lookup[hash(key) % tableSize] -> hosts的下标，-1表示空槽
+
+	mu sync.RWMutex
+}
+
+// NewMaglev 创建一个Maglev负载均衡器，tableSize应为质数，传0使用默认值
+func NewMaglev(hosts []string, tableSize int) *Maglev {
+	if tableSize <= 0 {
+		tableSize = defaultMaglevTableSize
+	}
+	m := &Maglev{tableSize: tableSize, hosts: make([]string, len(hosts))}
+	copy(m.hosts, hosts)
+	m.rebuild()
+	return m
+}
+
+// rebuild 按照论文的填充算法重新计算整张查找表，复杂度O(M)，调用方须持有写锁
+func (m *Maglev) rebuild() {
+	table := make([]int, m.tableSize)
+	for i := range table {
+		table[i] = -1
+	}
+
+	n := len(m.hosts)
+	if n == 0 {
+		m.lookup = table
+		return
+	}
+
+	permutation := make([][]int, n)
+	for i, host := range m.hosts {
+		offset := int(defaultHashFunc(host+"-offset") % uint64(m.tableSize))
+		skip := int(defaultHashFunc(host+"-skip")%uint64(m.tableSize-1)) + 1
+		perm := make([]int, m.tableSize)
+		for j := 0; j < m.tableSize; j++ {
+			perm[j] = (offset + j*skip) % m.tableSize
+		}
+		permutation[i] = perm
+	}
+
+	next := make([]int, n)
+	filled := 0
+	for filled < m.tableSize {
+		for i := 0; i < n && filled < m.tableSize; i++ {
+			slot := permutation[i][next[i]]
+			for table[slot] != -1 {
+				next[i]++
+				slot = permutation[i][next[i]]
+			}
+			table[slot] = i
+			next[i]++
+			filled++
+		}
+	}
+
+	m.lookup = table
+}
+
+func (m *Maglev) Pick(key string) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if len(m.hosts) == 0 {
+		return "", ErrHostNotFound
+	}
+	slot := defaultHashFunc(key) % uint64(m.tableSize)
+	idx := m.lookup[slot]
+	if idx < 0 {
+		return "", ErrHostNotFound
+	}
+	return m.hosts[idx], nil
+}
+
+// RegisterHost 追加一个host并重建整张查找表
+func (m *Maglev) RegisterHost(address string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, host := range m.hosts {
+		if host == address {
+			return ErrHostAlreadyExists
+		}
+	}
+	m.hosts = append(m.hosts, address)
+	m.rebuild()
+	return nil
+}
+
+// UnregisterHost 移除一个host并重建整张查找表
+func (m *Maglev) UnregisterHost(address string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, host := range m.hosts {
+		if host == address {
+			m.hosts = append(m.hosts[:i], m.hosts[i+1:]...)
+			m.rebuild()
+			return nil
+		}
+	}
+	return ErrHostNotFound
+}
+
+// IncLoad maglev不维护每个host的负载，空实现
+func (m *Maglev) IncLoad(address string) {}
+
+// DecLoad maglev不维护每个host的负载，空实现
+func (m *Maglev) DecLoad(address string) {}
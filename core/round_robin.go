@@ -0,0 +1,62 @@
+package core
+
+import "sync"
+
+// @Author KHighness
+// @Update 2022-07-02
+
+// RoundRobin 轮询负载均衡，依次将请求派发给已注册的缓存服务器
+type RoundRobin struct {
+	hosts []string
+	next  int
+	mu    sync.Mutex
+}
+
+// NewRoundRobin 创建一个RoundRobin负载均衡器
+func NewRoundRobin() *RoundRobin {
+	return &RoundRobin{hosts: make([]string, 0)}
+}
+
+func (rr *RoundRobin) Pick(key string) (string, error) {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	if len(rr.hosts) == 0 {
+		return "", ErrHostNotFound
+	}
+	host := rr.hosts[rr.next%len(rr.hosts)]
+	rr.next++
+	return host, nil
+}
+
+func (rr *RoundRobin) RegisterHost(address string) error {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	for _, host := range rr.hosts {
+		if host == address {
+			return ErrHostAlreadyExists
+		}
+	}
+	rr.hosts = append(rr.hosts, address)
+	return nil
+}
+
+func (rr *RoundRobin) UnregisterHost(address string) error {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	for i, host := range rr.hosts {
+		if host == address {
+			rr.hosts = append(rr.hosts[:i], rr.hosts[i+1:]...)
+			return nil
+		}
+	}
+	return ErrHostNotFound
+}
+
+// IncLoad 轮询策略不关心负载，空实现
+func (rr *RoundRobin) IncLoad(address string) {}
+
+// DecLoad 轮询策略不关心负载，空实现
+func (rr *RoundRobin) DecLoad(address string) {}
@@ -0,0 +1,69 @@
+package core
+
+import (
+	"fmt"
+	"testing"
+)
+
+// @Author KHighness
+// @Update 2022-08-20
+
+// approxRatio判断got是否落在want的tolerance相对误差范围内
+func approxRatio(t *testing.T, name string, got, want, tolerance float64) {
+	t.Helper()
+	if got < want*(1-tolerance) || got > want*(1+tolerance) {
+		t.Errorf("%s: ratio %.2f not within %.0f%% of expected %.2f", name, got, tolerance*100, want)
+	}
+}
+
+// TestRegisterHostWithWeightKeyDistribution验证权重为2的host在哈希环上
+// 分到的虚拟节点是权重为1的host的两倍，因而大量key下分到的请求数也应接近2倍
+func TestRegisterHostWithWeightKeyDistribution(t *testing.T) {
+	ch := NewConsistent(defaultReplicaNum, nil)
+	if err := ch.RegisterHostWithWeight("127.0.0.1:10000", 1); err != nil {
+		t.Fatalf("RegisterHostWithWeight(weight=1): %v", err)
+	}
+	if err := ch.RegisterHostWithWeight("127.0.0.1:10001", 2); err != nil {
+		t.Fatalf("RegisterHostWithWeight(weight=2): %v", err)
+	}
+
+	const numKeys = 20000
+	counts := make(map[string]int, 2)
+	for i := 0; i < numKeys; i++ {
+		host, err := ch.GetHostByKey(fmt.Sprintf("key-%d", i))
+		if err != nil {
+			t.Fatalf("GetHostByKey: %v", err)
+		}
+		counts[host]++
+	}
+
+	approxRatio(t, "weight-2 vs weight-1 key share",
+		float64(counts["127.0.0.1:10001"])/float64(counts["127.0.0.1:10000"]), 2, 0.2)
+}
+
+// TestRegisterHostWithWeightLoadAdmissions验证有界负载模式下，权重为2的host
+// 分摊到的总负载比例更高，因此在持续请求中获得的准入(IncLoad成功的Pick)次数
+// 也应接近权重为1的host的两倍
+func TestRegisterHostWithWeightLoadAdmissions(t *testing.T) {
+	ch := NewConsistent(defaultReplicaNum, nil)
+	if err := ch.RegisterHostWithWeight("127.0.0.1:10000", 1); err != nil {
+		t.Fatalf("RegisterHostWithWeight(weight=1): %v", err)
+	}
+	if err := ch.RegisterHostWithWeight("127.0.0.1:10001", 2); err != nil {
+		t.Fatalf("RegisterHostWithWeight(weight=2): %v", err)
+	}
+
+	const numKeys = 20000
+	admissions := make(map[string]int, 2)
+	for i := 0; i < numKeys; i++ {
+		host, err := ch.GetHostByKeyLeast(fmt.Sprintf("key-%d", i))
+		if err != nil {
+			t.Fatalf("GetHostByKeyLeast: %v", err)
+		}
+		ch.IncLoad(host)
+		admissions[host]++
+	}
+
+	approxRatio(t, "weight-2 vs weight-1 admissions",
+		float64(admissions["127.0.0.1:10001"])/float64(admissions["127.0.0.1:10000"]), 2, 0.2)
+}
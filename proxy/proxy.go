@@ -1,59 +1,126 @@
 package proxy
 
 import (
-	"fmt"
-	"io/ioutil"
+	"context"
 	"log"
-	"net/http"
 
 	"github.com/khighness/go-consistent-hashing/core"
+	"github.com/khighness/go-consistent-hashing/transport"
 )
 
 // @Author KHighness
-// @Update 2022-06-24
+// @Update 2022-09-01
 
 type Proxy struct {
-	ch *core.ConsistentHash
+	lb        core.LoadBalancer
+	transport transport.Transport
 }
 
-func NewProxy(consistentHash *core.ConsistentHash) *Proxy {
+// NewProxy 创建一个Proxy，t为nil时回退到HTTPTransport以保持向后兼容
+func NewProxy(lb core.LoadBalancer, t transport.Transport) *Proxy {
+	if t == nil {
+		t = transport.NewHTTPTransport()
+	}
 	proxy := &Proxy{
-		ch: consistentHash,
+		lb:        lb,
+		transport: t,
 	}
 	return proxy
 }
 
 func (p *Proxy) RegisterHost(address string) error {
-	err := p.ch.RegisterHost(address)
-	if err != nil {
-		return err
-	}
-	return nil
+	return p.lb.RegisterHost(address)
 }
 
 func (p *Proxy) UnregisterHost(address string) error {
-	err := p.ch.UnregisterHost(address)
-	if err != nil {
-		return err
-	}
-	return nil
+	return p.lb.UnregisterHost(address)
 }
 
 func (p *Proxy) GetKey(key string) (string, error) {
 	log.Printf("Request key: %s", key)
-	host, err := p.ch.GetHostByKey(key)
+	host, err := p.lb.Pick(key)
+	if err != nil {
+		return "", err
+	}
+
+	p.lb.IncLoad(host)
+	defer p.lb.DecLoad(host)
+
+	value, err := p.transport.Get(context.Background(), host, key)
 	if err != nil {
 		return "", err
 	}
+	log.Printf("Response from host %s: %s", host, value)
+
+	return value, nil
+}
+
+// replicatedResult 是一次副本读取的结果，按host携带返回值或错误，供read-repair日志使用
+type replicatedResult struct {
+	host string
+	val  string
+	err  error
+}
+
+// GetKeyReplicated 向key在哈希环上的前n个不同主机并行发起请求，一旦任意主机率先成功
+// 便立即返回其响应；当主副本在注册中心事件到达前崩溃或被拖慢时，调用方无需等待它，
+// 可以转而由先到达的次副本提供服务，其余尚未返回的主机转入后台做read-repair日志记录
+func (p *Proxy) GetKeyReplicated(key string, n int) (string, error) {
+	replicator, ok := p.lb.(core.Replicator)
+	if !ok {
+		return "", core.ErrReplicationUnsupported
+	}
 
-	resp, err := http.Get(fmt.Sprintf("http://%s?key=%s", host, key))
+	hosts, err := replicator.PickN(key, n)
 	if err != nil {
 		return "", err
 	}
+	if len(hosts) == 0 {
+		return "", core.ErrHostNotFound
+	}
+
+	results := make(chan replicatedResult, len(hosts))
+	for _, host := range hosts {
+		host := host
+		p.lb.IncLoad(host)
+		go func() {
+			defer p.lb.DecLoad(host)
+			value, err := p.transport.Get(context.Background(), host, key)
+			results <- replicatedResult{host: host, val: value, err: err}
+		}()
+	}
+
+	remaining := len(hosts)
+	for remaining > 0 {
+		r := <-results
+		remaining--
+		if r.err != nil {
+			log.Printf("Read-repair: host %s failed for key %s: %v", r.host, key, r.err)
+			continue
+		}
+		log.Printf("Served key %s from host %s", key, r.host)
+		if remaining > 0 {
+			go drainReplicatedResults(results, remaining, key, r.host, r.val)
+		}
+		return r.val, nil
+	}
 
-	defer resp.Body.Close()
-	body, _ := ioutil.ReadAll(resp.Body)
-	log.Printf("Response from host %s: %s", host, string(body))
+	return "", core.ErrAllHostsFailed
+}
 
-	return string(body), nil
-}
\ No newline at end of file
+// drainReplicatedResults 在首个成功响应已经返回给调用方之后，于后台消费剩余主机的响应，
+// 仅用于read-repair日志记录，不再阻塞请求路径
+func drainReplicatedResults(results <-chan replicatedResult, remaining int, key, servedHost, servedVal string) {
+	for i := 0; i < remaining; i++ {
+		r := <-results
+		if r.err != nil {
+			log.Printf("Read-repair: host %s failed for key %s: %v", r.host, key, r.err)
+			continue
+		}
+		if r.val != servedVal {
+			log.Printf("Read-repair: host %s returned %q for key %s, differs from value served by %s", r.host, r.val, key, servedHost)
+			continue
+		}
+		log.Printf("Read-repair: host %s also returned %q for key %s", r.host, r.val, key)
+	}
+}
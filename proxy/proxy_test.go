@@ -0,0 +1,134 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/khighness/go-consistent-hashing/core"
+)
+
+// @Author KHighness
+// @Update 2022-09-01
+
+// fakeHostBehavior描述fakeTransport对某个host的模拟响应：可选的延迟、返回值或错误
+type fakeHostBehavior struct {
+	delay time.Duration
+	value string
+	err   error
+}
+
+// fakeTransport是transport.Transport的测试替身，按host返回预设的行为，
+// 用来模拟主副本崩溃或被拖慢的场景
+type fakeTransport struct {
+	mu        sync.Mutex
+	behaviors map[string]fakeHostBehavior
+}
+
+func newFakeTransport() *fakeTransport {
+	return &fakeTransport{behaviors: make(map[string]fakeHostBehavior)}
+}
+
+func (f *fakeTransport) set(host string, b fakeHostBehavior) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.behaviors[host] = b
+}
+
+func (f *fakeTransport) Get(ctx context.Context, host, key string) (string, error) {
+	f.mu.Lock()
+	b := f.behaviors[host]
+	f.mu.Unlock()
+
+	if b.delay > 0 {
+		select {
+		case <-time.After(b.delay):
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+	if b.err != nil {
+		return "", b.err
+	}
+	return b.value, nil
+}
+
+func (f *fakeTransport) Close() error { return nil }
+
+// pickPrimaryAndSecondary返回key在balancer上的前两个候选host
+func pickPrimaryAndSecondary(t *testing.T, balancer *core.ConsistentHashBalancer, key string) (string, string) {
+	t.Helper()
+	hosts, err := balancer.PickN(key, 2)
+	if err != nil {
+		t.Fatalf("PickN(%q, 2): %v", key, err)
+	}
+	if len(hosts) != 2 {
+		t.Fatalf("PickN(%q, 2) returned %d hosts, want 2", key, len(hosts))
+	}
+	return hosts[0], hosts[1]
+}
+
+// TestGetKeyReplicatedFailsOverToSecondary模拟主副本在请求期间崩溃(连接被拒绝)，
+// 验证调用方仍能拿到次副本的响应，而不是整体失败
+func TestGetKeyReplicatedFailsOverToSecondary(t *testing.T) {
+	balancer := core.NewConsistentHashBalancer(10, nil)
+	for _, host := range []string{"127.0.0.1:10000", "127.0.0.1:10001"} {
+		if err := balancer.RegisterHost(host); err != nil {
+			t.Fatalf("RegisterHost(%s): %v", host, err)
+		}
+	}
+
+	const key = "some-key"
+	primary, secondary := pickPrimaryAndSecondary(t, balancer, key)
+
+	ft := newFakeTransport()
+	ft.set(primary, fakeHostBehavior{err: errors.New("connection refused")})
+	ft.set(secondary, fakeHostBehavior{value: "value-from-secondary"})
+
+	p := NewProxy(balancer, ft)
+	val, err := p.GetKeyReplicated(key, 2)
+	if err != nil {
+		t.Fatalf("GetKeyReplicated(%q, 2): %v", key, err)
+	}
+	if val != "value-from-secondary" {
+		t.Fatalf("GetKeyReplicated(%q, 2) = %q, want value served by secondary", key, val)
+	}
+}
+
+// TestGetKeyReplicatedReturnsOnFirstSuccess验证只要有一个副本先返回成功响应，
+// 调用方立即拿到结果，不会被一个响应缓慢的副本拖住
+func TestGetKeyReplicatedReturnsOnFirstSuccess(t *testing.T) {
+	balancer := core.NewConsistentHashBalancer(10, nil)
+	for _, host := range []string{"127.0.0.1:10000", "127.0.0.1:10001"} {
+		if err := balancer.RegisterHost(host); err != nil {
+			t.Fatalf("RegisterHost(%s): %v", host, err)
+		}
+	}
+
+	const key = "some-key"
+	slow, fast := pickPrimaryAndSecondary(t, balancer, key)
+
+	ft := newFakeTransport()
+	ft.set(slow, fakeHostBehavior{delay: 500 * time.Millisecond, value: "slow-value"})
+	ft.set(fast, fakeHostBehavior{value: "fast-value"})
+
+	p := NewProxy(balancer, ft)
+
+	start := time.Now()
+	val, err := p.GetKeyReplicated(key, 2)
+	elapsed := time.Since(start)
+	if err != nil {
+		t.Fatalf("GetKeyReplicated(%q, 2): %v", key, err)
+	}
+	if val != "fast-value" {
+		t.Fatalf("GetKeyReplicated(%q, 2) = %q, want the fast replica's value", key, val)
+	}
+	if elapsed > 200*time.Millisecond {
+		t.Fatalf("GetKeyReplicated(%q, 2) took %v, looks like it waited for the slow replica", key, elapsed)
+	}
+
+	// 给后台read-repair的drain goroutine留出时间，避免它在测试进程退出后才跑
+	time.Sleep(600 * time.Millisecond)
+}
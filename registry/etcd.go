@@ -0,0 +1,146 @@
+package registry
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// @Author KHighness
+// @Update 2022-07-15
+
+const (
+	// servicePrefix 服务注册信息在etcd中的公共前缀
+	servicePrefix = "/go-consistent-hashing/hosts/"
+
+	// leaseTTL 租约有效期，单位秒，超过该时间未续约的host会被etcd自动摘除
+	leaseTTL = 10
+)
+
+// EtcdRegistry 基于etcd v3的Registry实现，通过租约+自动续约保证崩溃后的host能自动摘除，
+// 取代了此前KVStoreServer手动调用/register、/unregister的自注册方式
+type EtcdRegistry struct {
+	client *clientv3.Client
+
+	mu      sync.Mutex
+	leaseID clientv3.LeaseID
+	cancel  context.CancelFunc
+}
+
+// NewEtcdRegistry 创建一个基于etcd的Registry
+func NewEtcdRegistry(endpoints []string) (*EtcdRegistry, error) {
+	client, err := clientv3.New(clientv3.Config{Endpoints: endpoints})
+	if err != nil {
+		return nil, err
+	}
+	return &EtcdRegistry{client: client}, nil
+}
+
+func serviceKey(host string) string {
+	return servicePrefix + host
+}
+
+// Register 申请一个租约并把host写入etcd，随后启动续约goroutine保持租约存活
+func (r *EtcdRegistry) Register(ctx context.Context, host string) error {
+	lease, err := r.client.Grant(ctx, leaseTTL)
+	if err != nil {
+		return err
+	}
+
+	if _, err = r.client.Put(ctx, serviceKey(host), host, clientv3.WithLease(lease.ID)); err != nil {
+		return err
+	}
+
+	keepAliveCtx, cancel := context.WithCancel(context.Background())
+	keepAliveCh, err := r.client.KeepAlive(keepAliveCtx, lease.ID)
+	if err != nil {
+		cancel()
+		return err
+	}
+
+	r.mu.Lock()
+	r.leaseID = lease.ID
+	r.cancel = cancel
+	r.mu.Unlock()
+
+	go func() {
+		for range keepAliveCh {
+			// 消费续约响应，保持租约存活；续约周期由etcd客户端自行管理
+		}
+		log.Printf("Lease for host %s expired or revoked", host)
+	}()
+
+	log.Printf("Registered host %s to etcd with lease %d", host, lease.ID)
+	return nil
+}
+
+// Unregister 主动撤销租约，host会随之从etcd中移除
+func (r *EtcdRegistry) Unregister(ctx context.Context, host string) error {
+	r.mu.Lock()
+	cancel := r.cancel
+	leaseID := r.leaseID
+	r.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	if leaseID != 0 {
+		if _, err := r.client.Revoke(ctx, leaseID); err != nil {
+			return err
+		}
+	}
+
+	_, err := r.client.Delete(ctx, serviceKey(host))
+	return err
+}
+
+// Watch 从指定revision之后订阅服务前缀下的变化，转换为Added/Removed事件；
+// rev为0时从当前版本开始订阅
+func (r *EtcdRegistry) Watch(ctx context.Context, rev int64) (<-chan Event, error) {
+	events := make(chan Event)
+	opts := []clientv3.OpOption{clientv3.WithPrefix()}
+	if rev > 0 {
+		opts = append(opts, clientv3.WithRev(rev+1))
+	}
+	watchCh := r.client.Watch(ctx, servicePrefix, opts...)
+
+	go func() {
+		defer close(events)
+		for resp := range watchCh {
+			for _, evt := range resp.Events {
+				host := strings.TrimPrefix(string(evt.Kv.Key), servicePrefix)
+				switch evt.Type {
+				case clientv3.EventTypePut:
+					events <- Event{Type: EventAdded, Host: host}
+				case clientv3.EventTypeDelete:
+					events <- Event{Type: EventRemoved, Host: host}
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// AllServiceInfo 获取当前前缀下所有已注册的host快照，以及该快照的revision，
+// 调用方可将其传给Watch以衔接上快照之后的变更，既不漏事件也不重复应用
+func (r *EtcdRegistry) AllServiceInfo(ctx context.Context) ([]string, int64, error) {
+	resp, err := r.client.Get(ctx, servicePrefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, 0, err
+	}
+
+	hosts := make([]string, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		hosts = append(hosts, strings.TrimPrefix(string(kv.Key), servicePrefix))
+	}
+	return hosts, resp.Header.Revision, nil
+}
+
+// Close 释放etcd客户端连接
+func (r *EtcdRegistry) Close() error {
+	return r.client.Close()
+}
@@ -0,0 +1,34 @@
+package registry
+
+import "context"
+
+// @Author KHighness
+// @Update 2022-07-15
+
+// EventType 描述一次服务发现事件的类型
+type EventType int
+
+const (
+	EventAdded EventType = iota
+	EventRemoved
+)
+
+// Event 服务发现的变更事件
+type Event struct {
+	Type EventType
+	Host string
+}
+
+// Registry 服务注册发现接口，屏蔽底层注册中心(etcd/zk/...)的实现细节
+type Registry interface {
+	// Register 注册一台缓存服务器，实现应当维持租约，以便该服务器崩溃后能被自动摘除
+	Register(ctx context.Context, host string) error
+	// Unregister 主动注销一台缓存服务器
+	Unregister(ctx context.Context, host string) error
+	// Watch 从指定revision之后订阅注册中心的成员变化，返回的channel会持续推送事件直至ctx结束；
+	// rev传0表示从当前版本开始订阅，传AllServiceInfo返回的revision可以与该次快照无缝衔接，
+	// 既不漏过快照之后发生的事件，也不会重复应用快照已经包含的事件
+	Watch(ctx context.Context, rev int64) (<-chan Event, error)
+	// AllServiceInfo 获取当前已注册的全部缓存服务器快照，以及该快照对应的revision
+	AllServiceInfo(ctx context.Context) (hosts []string, revision int64, err error)
+}
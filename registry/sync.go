@@ -0,0 +1,48 @@
+package registry
+
+import (
+	"context"
+	"log"
+
+	"github.com/khighness/go-consistent-hashing/core"
+)
+
+// @Author KHighness
+// @Update 2022-07-15
+
+// Sync 启动时先用AllServiceInfo获取一份host快照灌入哈希环，再从该快照对应的revision
+// 开始Watch，从而衔接上快照之后的变更；否则proxy若在kv-store节点都已上线之后才启动，
+// Watch只会推送它启动之后发生的事件，会得到一个永远空的环，直到下一次上下线事件发生
+func Sync(ctx context.Context, reg Registry, ch *core.ConsistentHash) error {
+	hosts, rev, err := reg.AllServiceInfo(ctx)
+	if err != nil {
+		return err
+	}
+	for _, host := range hosts {
+		if err := ch.RegisterHost(host); err != nil && err != core.ErrHostAlreadyExists {
+			log.Printf("Sync: register host %s failed: %v", host, err)
+		}
+	}
+
+	events, err := reg.Watch(ctx, rev)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		for event := range events {
+			switch event.Type {
+			case EventAdded:
+				if err := ch.RegisterHost(event.Host); err != nil && err != core.ErrHostAlreadyExists {
+					log.Printf("Sync: register host %s failed: %v", event.Host, err)
+				}
+			case EventRemoved:
+				if err := ch.UnregisterHost(event.Host); err != nil && err != core.ErrHostNotFound {
+					log.Printf("Sync: unregister host %s failed: %v", event.Host, err)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
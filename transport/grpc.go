@@ -0,0 +1,111 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"google.golang.org/grpc"
+
+	"github.com/khighness/go-consistent-hashing/core"
+	"github.com/khighness/go-consistent-hashing/transport/kvpb"
+)
+
+// @Author KHighness
+// @Update 2022-09-01
+
+// GRPCTransport 维护每个host的长连接池，取代每次请求都重新建连的HTTP方案；
+// 它同时实现core.Observer，以便registry发现的host变化能驱动连接池的增减
+type GRPCTransport struct {
+	dialOpts []grpc.DialOption
+
+	mu    sync.RWMutex
+	conns map[string]*grpc.ClientConn
+}
+
+// NewGRPCTransport 创建一个GRPCTransport，dialOpts透传给grpc.Dial(如TLS、keepalive参数)
+func NewGRPCTransport(dialOpts ...grpc.DialOption) *GRPCTransport {
+	return &GRPCTransport{
+		dialOpts: dialOpts,
+		conns:    make(map[string]*grpc.ClientConn),
+	}
+}
+
+// OnHostAdded 实现core.Observer，为新主机建立保活的gRPC连接
+func (t *GRPCTransport) OnHostAdded(address string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.conns[address]; ok {
+		return
+	}
+	conn, err := grpc.Dial(address, t.dialOpts...)
+	if err != nil {
+		return
+	}
+	t.conns[address] = conn
+}
+
+// OnHostRemoved 实现core.Observer，关闭被摘除主机的连接
+func (t *GRPCTransport) OnHostRemoved(address string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if conn, ok := t.conns[address]; ok {
+		_ = conn.Close()
+		delete(t.conns, address)
+	}
+}
+
+// connFor 获取host对应的连接，连接池中没有时按需建连兜底
+// (例如host变化发生在registry的OnHostAdded回调之前)
+func (t *GRPCTransport) connFor(host string) (*grpc.ClientConn, error) {
+	t.mu.RLock()
+	conn, ok := t.conns[host]
+	t.mu.RUnlock()
+	if ok {
+		return conn, nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if conn, ok = t.conns[host]; ok {
+		return conn, nil
+	}
+	conn, err := grpc.Dial(host, t.dialOpts...)
+	if err != nil {
+		return nil, err
+	}
+	t.conns[host] = conn
+	return conn, nil
+}
+
+// Get 调用kv.proto里定义的KVService.Get取值
+func (t *GRPCTransport) Get(ctx context.Context, host, key string) (string, error) {
+	conn, err := t.connFor(host)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := kvpb.NewKVServiceClient(conn).Get(ctx, &kvpb.GetReq{Key: key})
+	if err != nil {
+		return "", fmt.Errorf("grpc get %s from %s: %w", key, host, err)
+	}
+	return resp.GetValue(), nil
+}
+
+func (t *GRPCTransport) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var lastErr error
+	for host, conn := range t.conns {
+		if err := conn.Close(); err != nil {
+			lastErr = err
+		}
+		delete(t.conns, host)
+	}
+	return lastErr
+}
+
+var _ core.Observer = (*GRPCTransport)(nil)
@@ -0,0 +1,46 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// @Author KHighness
+// @Update 2022-09-01
+
+// HTTPTransport 是Transport的默认实现，沿用既有的一次性HTTP GET方式，
+// 作为向后兼容方案保留：请求经由http.DefaultClient发出，空闲的TCP连接由其默认的
+// keep-alive机制复用
+type HTTPTransport struct {
+	client *http.Client
+}
+
+// NewHTTPTransport 创建一个HTTPTransport
+func NewHTTPTransport() *HTTPTransport {
+	return &HTTPTransport{client: http.DefaultClient}
+}
+
+func (t *HTTPTransport) Get(ctx context.Context, host, key string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("http://%s?key=%s", host, key), nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+func (t *HTTPTransport) Close() error {
+	return nil
+}
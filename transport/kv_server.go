@@ -0,0 +1,37 @@
+package transport
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	"github.com/khighness/go-consistent-hashing/transport/kvpb"
+)
+
+// @Author KHighness
+// @Update 2022-09-01
+
+// KVServer 是KVService.Get的服务端实现需要满足的接口
+type KVServer interface {
+	Get(ctx context.Context, key string) (string, error)
+}
+
+// kvServiceAdapter 把面向业务的KVServer适配成kv.proto生成的kvpb.KVServiceServer，
+// 使cmd/kv-server的实现无需直接依赖生成代码里的消息类型
+type kvServiceAdapter struct {
+	kvpb.UnimplementedKVServiceServer
+	srv KVServer
+}
+
+func (a *kvServiceAdapter) Get(ctx context.Context, req *kvpb.GetReq) (*kvpb.GetResp, error) {
+	value, err := a.srv.Get(ctx, req.GetKey())
+	if err != nil {
+		return nil, err
+	}
+	return &kvpb.GetResp{Value: value, Found: true}, nil
+}
+
+// RegisterKVServiceServer 将KVServer实现注册到grpc.Server上
+func RegisterKVServiceServer(s *grpc.Server, srv KVServer) {
+	kvpb.RegisterKVServiceServer(s, &kvServiceAdapter{srv: srv})
+}
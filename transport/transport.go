@@ -0,0 +1,14 @@
+package transport
+
+import "context"
+
+// @Author KHighness
+// @Update 2022-09-01
+
+// Transport 屏蔽代理到缓存服务器的数据面协议细节(HTTP/gRPC/...)
+type Transport interface {
+	// Get 从指定host按key取值
+	Get(ctx context.Context, host, key string) (string, error)
+	// Close 释放transport持有的底层连接
+	Close() error
+}
@@ -0,0 +1,120 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"google.golang.org/grpc"
+)
+
+// @Author KHighness
+// @Update 2022-09-01
+
+// benchKVServer是transport.KVServer的一个简单实现，只是返回"v-"加上key，
+// 足以用来比较GRPCTransport与HTTPTransport本身的开销
+type benchKVServer struct{}
+
+func (benchKVServer) Get(ctx context.Context, key string) (string, error) {
+	return "v-" + key, nil
+}
+
+// newBenchGRPCTransport启动一个承载KVService的本地gRPC server，返回连到它的
+// GRPCTransport、server地址，以及测试结束时需要调用的清理函数
+func newBenchGRPCTransport(b *testing.B) (*GRPCTransport, string, func()) {
+	b.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		b.Fatalf("net.Listen: %v", err)
+	}
+	server := grpc.NewServer()
+	RegisterKVServiceServer(server, benchKVServer{})
+	go func() { _ = server.Serve(lis) }()
+
+	address := lis.Addr().String()
+	transport := NewGRPCTransport(grpc.WithInsecure(), grpc.WithBlock())
+	// 建连放在计时之外，基准只衡量稳态下单次Get的开销
+	if _, err := transport.connFor(address); err != nil {
+		b.Fatalf("connFor(%s): %v", address, err)
+	}
+
+	cleanup := func() {
+		_ = transport.Close()
+		server.Stop()
+	}
+	return transport, address, cleanup
+}
+
+// newBenchHTTPServer启动一个与cmd/kv-server等价的HTTP server，用作对照组
+func newBenchHTTPServer() (*httptest.Server, string) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		key := r.Form.Get("key")
+		_, _ = fmt.Fprintf(w, "v-%s", key)
+	}))
+	return server, server.Listener.Addr().String()
+}
+
+// percentile对一组耗时取第p百分位(0<p<100)，调用前values必须已经按升序排好
+func percentile(sortedValues []float64, p float64) float64 {
+	if len(sortedValues) == 0 {
+		return 0
+	}
+	idx := int(float64(len(sortedValues)-1) * p / 100)
+	return sortedValues[idx]
+}
+
+// BenchmarkGRPCTransportGet衡量GRPCTransport.Get在保活连接池下的单次取值开销，
+// 同时记录p99延迟，便于与BenchmarkHTTPTransportGet对照
+func BenchmarkGRPCTransportGet(b *testing.B) {
+	transport, address, cleanup := newBenchGRPCTransport(b)
+	defer cleanup()
+
+	latencies := make([]float64, 0, b.N)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		start := b.Elapsed()
+		if _, err := transport.Get(ctx, address, "key"); err != nil {
+			b.Fatalf("Get: %v", err)
+		}
+		latencies = append(latencies, float64(b.Elapsed()-start))
+	}
+	b.StopTimer()
+
+	sort.Float64s(latencies)
+	b.ReportMetric(percentile(latencies, 99), "p99-ns/op")
+}
+
+// BenchmarkHTTPTransportGet是HTTPTransport.Get的对照组：每次Get都是一次完整的
+// HTTP请求-响应往返(TCP连接由http.DefaultClient的keep-alive复用)，用来与
+// BenchmarkGRPCTransportGet对照
+func BenchmarkHTTPTransportGet(b *testing.B) {
+	server, address := newBenchHTTPServer()
+	defer server.Close()
+
+	transport := NewHTTPTransport()
+	latencies := make([]float64, 0, b.N)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		start := b.Elapsed()
+		if _, err := transport.Get(ctx, address, "key"); err != nil {
+			b.Fatalf("Get: %v", err)
+		}
+		latencies = append(latencies, float64(b.Elapsed()-start))
+	}
+	b.StopTimer()
+
+	sort.Float64s(latencies)
+	b.ReportMetric(percentile(latencies, 99), "p99-ns/op")
+}